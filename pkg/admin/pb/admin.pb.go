@@ -0,0 +1,366 @@
+// Hand-maintained stub for admin.proto: this tree has no protoc/protoc-gen-go
+// available to generate it for real, so the types and gRPC service glue
+// below are written out by hand to match what protoc-gen-go would produce.
+// If protoc-gen-go ever runs against admin.proto in this repo, regenerate
+// this file from that output instead of hand-editing it further.
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type DumpRequest struct {
+}
+
+func (m *DumpRequest) Reset()         { *m = DumpRequest{} }
+func (m *DumpRequest) String() string { return proto.CompactTextString(m) }
+func (*DumpRequest) ProtoMessage()    {}
+
+type DumpResponse struct {
+	Json []byte `protobuf:"bytes,1,opt,name=json,proto3" json:"json,omitempty"`
+}
+
+func (m *DumpResponse) Reset()         { *m = DumpResponse{} }
+func (m *DumpResponse) String() string { return proto.CompactTextString(m) }
+func (*DumpResponse) ProtoMessage()    {}
+
+func (m *DumpResponse) GetJson() []byte {
+	if m != nil {
+		return m.Json
+	}
+	return nil
+}
+
+type StreamStatsRequest struct {
+	IntervalSeconds int32 `protobuf:"varint,1,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+}
+
+func (m *StreamStatsRequest) Reset()         { *m = StreamStatsRequest{} }
+func (m *StreamStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamStatsRequest) ProtoMessage()    {}
+
+func (m *StreamStatsRequest) GetIntervalSeconds() int32 {
+	if m != nil {
+		return m.IntervalSeconds
+	}
+	return 0
+}
+
+type StreamStatsResponse struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *StreamStatsResponse) Reset()         { *m = StreamStatsResponse{} }
+func (m *StreamStatsResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamStatsResponse) ProtoMessage()    {}
+
+func (m *StreamStatsResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type SetLogLevelRequest struct {
+	Level string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+}
+
+func (m *SetLogLevelRequest) Reset()         { *m = SetLogLevelRequest{} }
+func (m *SetLogLevelRequest) String() string { return proto.CompactTextString(m) }
+func (*SetLogLevelRequest) ProtoMessage()    {}
+
+func (m *SetLogLevelRequest) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type SetLogLevelResponse struct {
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (m *SetLogLevelResponse) Reset()         { *m = SetLogLevelResponse{} }
+func (m *SetLogLevelResponse) String() string { return proto.CompactTextString(m) }
+func (*SetLogLevelResponse) ProtoMessage()    {}
+
+func (m *SetLogLevelResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type UpdateListenerTLSRequest struct {
+	Listener   string `protobuf:"bytes,1,opt,name=listener,proto3" json:"listener,omitempty"`
+	Inspector  bool   `protobuf:"varint,2,opt,name=inspector,proto3" json:"inspector,omitempty"`
+	TlsContext []byte `protobuf:"bytes,3,opt,name=tls_context,json=tlsContext,proto3" json:"tls_context,omitempty"`
+}
+
+func (m *UpdateListenerTLSRequest) Reset()         { *m = UpdateListenerTLSRequest{} }
+func (m *UpdateListenerTLSRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateListenerTLSRequest) ProtoMessage()    {}
+
+func (m *UpdateListenerTLSRequest) GetListener() string {
+	if m != nil {
+		return m.Listener
+	}
+	return ""
+}
+
+func (m *UpdateListenerTLSRequest) GetInspector() bool {
+	if m != nil {
+		return m.Inspector
+	}
+	return false
+}
+
+func (m *UpdateListenerTLSRequest) GetTlsContext() []byte {
+	if m != nil {
+		return m.TlsContext
+	}
+	return nil
+}
+
+type UpdateListenerTLSResponse struct {
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (m *UpdateListenerTLSResponse) Reset()         { *m = UpdateListenerTLSResponse{} }
+func (m *UpdateListenerTLSResponse) String() string { return proto.CompactTextString(m) }
+func (*UpdateListenerTLSResponse) ProtoMessage()    {}
+
+func (m *UpdateListenerTLSResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*DumpRequest)(nil), "pb.DumpRequest")
+	proto.RegisterType((*DumpResponse)(nil), "pb.DumpResponse")
+	proto.RegisterType((*StreamStatsRequest)(nil), "pb.StreamStatsRequest")
+	proto.RegisterType((*StreamStatsResponse)(nil), "pb.StreamStatsResponse")
+	proto.RegisterType((*SetLogLevelRequest)(nil), "pb.SetLogLevelRequest")
+	proto.RegisterType((*SetLogLevelResponse)(nil), "pb.SetLogLevelResponse")
+	proto.RegisterType((*UpdateListenerTLSRequest)(nil), "pb.UpdateListenerTLSRequest")
+	proto.RegisterType((*UpdateListenerTLSResponse)(nil), "pb.UpdateListenerTLSResponse")
+}
+
+// AdminServiceClient is the client API for AdminService service.
+type AdminServiceClient interface {
+	Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpResponse, error)
+	StreamStats(ctx context.Context, in *StreamStatsRequest, opts ...grpc.CallOption) (AdminService_StreamStatsClient, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	UpdateListenerTLS(ctx context.Context, in *UpdateListenerTLSRequest, opts ...grpc.CallOption) (*UpdateListenerTLSResponse, error)
+}
+
+type adminServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminServiceClient(cc *grpc.ClientConn) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpResponse, error) {
+	out := new(DumpResponse)
+	err := c.cc.Invoke(ctx, "/pb.AdminService/Dump", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) StreamStats(ctx context.Context, in *StreamStatsRequest, opts ...grpc.CallOption) (AdminService_StreamStatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AdminService_serviceDesc.Streams[0], "/pb.AdminService/StreamStats", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminServiceStreamStatsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AdminService_StreamStatsClient interface {
+	Recv() (*StreamStatsResponse, error)
+	grpc.ClientStream
+}
+
+type adminServiceStreamStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminServiceStreamStatsClient) Recv() (*StreamStatsResponse, error) {
+	m := new(StreamStatsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, "/pb.AdminService/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) UpdateListenerTLS(ctx context.Context, in *UpdateListenerTLSRequest, opts ...grpc.CallOption) (*UpdateListenerTLSResponse, error) {
+	out := new(UpdateListenerTLSResponse)
+	err := c.cc.Invoke(ctx, "/pb.AdminService/UpdateListenerTLS", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+type AdminServiceServer interface {
+	Dump(context.Context, *DumpRequest) (*DumpResponse, error)
+	StreamStats(*StreamStatsRequest, AdminService_StreamStatsServer) error
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	UpdateListenerTLS(context.Context, *UpdateListenerTLSRequest) (*UpdateListenerTLSResponse, error)
+}
+
+// UnimplementedAdminServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServiceServer struct{}
+
+func (*UnimplementedAdminServiceServer) Dump(ctx context.Context, req *DumpRequest) (*DumpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Dump not implemented")
+}
+func (*UnimplementedAdminServiceServer) StreamStats(req *StreamStatsRequest, srv AdminService_StreamStatsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamStats not implemented")
+}
+func (*UnimplementedAdminServiceServer) SetLogLevel(ctx context.Context, req *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (*UnimplementedAdminServiceServer) UpdateListenerTLS(ctx context.Context, req *UpdateListenerTLSRequest) (*UpdateListenerTLSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateListenerTLS not implemented")
+}
+
+func RegisterAdminServiceServer(s *grpc.Server, srv AdminServiceServer) {
+	s.RegisterService(&_AdminService_serviceDesc, srv)
+}
+
+func _AdminService_Dump_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).Dump(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.AdminService/Dump",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).Dump(ctx, req.(*DumpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_StreamStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServiceServer).StreamStats(m, &adminServiceStreamStatsServer{stream})
+}
+
+type AdminService_StreamStatsServer interface {
+	Send(*StreamStatsResponse) error
+	grpc.ServerStream
+}
+
+type adminServiceStreamStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminServiceStreamStatsServer) Send(m *StreamStatsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AdminService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.AdminService/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_UpdateListenerTLS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateListenerTLSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).UpdateListenerTLS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.AdminService/UpdateListenerTLS",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).UpdateListenerTLS(ctx, req.(*UpdateListenerTLSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _AdminService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Dump",
+			Handler:    _AdminService_Dump_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _AdminService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "UpdateListenerTLS",
+			Handler:    _AdminService_UpdateListenerTLS_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamStats",
+			Handler:       _AdminService_StreamStats_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "admin.proto",
+}
@@ -18,6 +18,7 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/alipay/sofa-mosn/pkg/admin/store"
@@ -26,6 +27,7 @@ import (
 	"github.com/alipay/sofa-mosn/pkg/metrics"
 	"github.com/alipay/sofa-mosn/pkg/metrics/sink/console"
 	"github.com/alipay/sofa-mosn/pkg/server"
+	streamhttp "github.com/alipay/sofa-mosn/pkg/stream/http"
 	"github.com/valyala/fasthttp"
 )
 
@@ -104,4 +106,69 @@ func updateListenerTLS(ctx *fasthttp.RequestCtx) {
 	}
 	log.DefaultLogger.Infof("listener %s's tls config has been changed, inspector: %v, tlsstart: %v", data.Listener, data.Inspetcor, data.TLSConfig.Status)
 	ctx.WriteString("update tls success\n")
-}
\ No newline at end of file
+}
+
+// POST Data Format
+/*
+{
+	"listener": "string",
+	"enabled": bool
+}
+*/
+type webSocketUpdate struct {
+	Listener string `json:"listener"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// updateListenerWebSocket lets operators flip WebSocket upgrade handling
+// for a listener on or off without restarting MOSN, the same way
+// updateListenerTLS hot-updates a listener's TLS config.
+func updateListenerWebSocket(ctx *fasthttp.RequestCtx) {
+	body := ctx.Request.Body()
+	data := &webSocketUpdate{}
+	if err := json.Unmarshal(body, data); err != nil {
+		ctx.SetStatusCode(400)
+		ctx.Write([]byte(`{ error: "invalid post data"}`))
+		return
+	}
+	streamhttp.SetWebSocketTunnel(data.Listener, data.Enabled)
+	log.DefaultLogger.Infof("listener %s's websocket tunneling has been set to enabled=%v", data.Listener, data.Enabled)
+	ctx.WriteString("update websocket tunnel success\n")
+}
+
+// POST Data Format
+/*
+{
+	"listener": "string",
+	"allow": ["host:port", "*.example.com:443"],
+	"direct_dial": false
+}
+*/
+type connectUpdate struct {
+	Listener   string   `json:"listener"`
+	Allow      []string `json:"allow"`
+	DirectDial bool     `json:"direct_dial"`
+}
+
+// updateListenerConnect replaces a listener's CONNECT destination
+// allowlist and its direct-dial fallback setting, the same way
+// updateListenerTLS and updateListenerWebSocket hot-update their
+// respective per-listener settings. An empty or missing allowlist denies
+// all CONNECT tunnels, so the default is closed; direct_dial defaults to
+// false, so CONNECT is dialed only through streamhttp.ConnectClusterDialer
+// (and refused while that is unset) unless an operator explicitly opts a
+// listener into dialing destinations directly.
+func updateListenerConnect(ctx *fasthttp.RequestCtx) {
+	body := ctx.Request.Body()
+	data := &connectUpdate{}
+	if err := json.Unmarshal(body, data); err != nil {
+		ctx.SetStatusCode(400)
+		ctx.Write([]byte(`{ error: "invalid post data"}`))
+		return
+	}
+	streamhttp.SetConnectAllowlist(data.Listener, data.Allow)
+	streamhttp.SetConnectDirectDial(data.Listener, data.DirectDial)
+	log.DefaultLogger.Infof("listener %s's CONNECT allowlist has been updated to %v, direct_dial=%v",
+		data.Listener, data.Allow, data.DirectDial)
+	ctx.WriteString("update connect allowlist success\n")
+}
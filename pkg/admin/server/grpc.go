@@ -0,0 +1,208 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/alipay/sofa-mosn/pkg/admin/pb"
+	"github.com/alipay/sofa-mosn/pkg/admin/store"
+	"github.com/alipay/sofa-mosn/pkg/api/v2"
+	"github.com/alipay/sofa-mosn/pkg/log"
+	"github.com/alipay/sofa-mosn/pkg/metrics"
+	"github.com/alipay/sofa-mosn/pkg/metrics/sink/console"
+	"github.com/alipay/sofa-mosn/pkg/server"
+	"github.com/soheilhy/cmux"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultStatsInterval = 5 * time.Second
+
+// grpcServer implements pb.AdminServiceServer directly on top of the same
+// calls the fasthttp handlers above use (store.Dump, metrics.GetAll,
+// levelMap, server.GetListenerAdapterInstance), so the gRPC and HTTP admin
+// surfaces can't drift out of sync with each other.
+type grpcServer struct {
+	pb.UnimplementedAdminServiceServer
+}
+
+func (s *grpcServer) Dump(ctx context.Context, req *pb.DumpRequest) (*pb.DumpResponse, error) {
+	buf, err := store.Dump()
+	if err != nil {
+		log.DefaultLogger.Errorf("Admin gRPC: Dump failed, cause by %s", err)
+		return nil, status.Errorf(codes.Internal, "internal error")
+	}
+	return &pb.DumpResponse{Json: buf}, nil
+}
+
+func (s *grpcServer) StreamStats(req *pb.StreamStatsRequest, stream pb.AdminService_StreamStatsServer) error {
+	interval := time.Duration(req.GetIntervalSeconds()) * time.Second
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+			var buf bytes.Buffer
+			sink := console.NewConsoleSink(&buf)
+			sink.Flush(metrics.GetAll())
+
+			if err := stream.Send(&pb.StreamStatsResponse{Data: buf.Bytes()}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *grpcServer) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+	level, ok := levelMap[req.GetLevel()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown log level")
+	}
+
+	log.DefaultLogger.Level = level
+	log.DefaultLogger.Infof("DefaultLogger level has been changed to %s", req.GetLevel())
+	return &pb.SetLogLevelResponse{Message: "update logger success"}, nil
+}
+
+func (s *grpcServer) UpdateListenerTLS(ctx context.Context, req *pb.UpdateListenerTLSRequest) (*pb.UpdateListenerTLSResponse, error) {
+	tlsConfig := &v2.TLSConfig{}
+	if err := json.Unmarshal(req.GetTlsContext(), tlsConfig); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tls_context: %s", err)
+	}
+
+	adapter := server.GetListenerAdapterInstance()
+	// server can be "", so use the default server. currently we only support one server, so use "" is ok
+	if err := adapter.UpdateListenerTLS("", req.GetListener(), req.GetInspector(), tlsConfig); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	log.DefaultLogger.Infof("listener %s's tls config has been changed via gRPC, inspector: %v, tlsstart: %v",
+		req.GetListener(), req.GetInspector(), tlsConfig.Status)
+	return &pb.UpdateListenerTLSResponse{Message: "update tls success"}, nil
+}
+
+// NewGRPCServer builds the gRPC admin server described by pkg/admin/pb.
+// Callers that want it on a dedicated port can just call Serve(lis) on it;
+// callers that want to share a single port with the fasthttp admin API
+// should run it behind ServeMux instead.
+func NewGRPCServer() *grpc.Server {
+	s := grpc.NewServer()
+	pb.RegisterAdminServiceServer(s, &grpcServer{})
+	return s
+}
+
+// ServeMux splits a single listener between the gRPC admin API and the
+// fasthttp admin API using cmux, routing HTTP/2 gRPC requests to grpcSrv
+// and everything else to httpServe. It blocks until the listener or one of
+// the two servers fails.
+func ServeMux(l net.Listener, grpcSrv *grpc.Server, httpServe func(net.Listener) error) error {
+	m := cmux.New(l)
+	grpcL := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+	httpL := m.Match(cmux.Any())
+
+	errCh := make(chan error, 3)
+	go func() { errCh <- grpcSrv.Serve(grpcL) }()
+	go func() { errCh <- httpServe(httpL) }()
+	go func() { errCh <- m.Serve() }()
+
+	return <-errCh
+}
+
+// Config describes how to bring up the admin API.
+type Config struct {
+	// Address is the listener the fasthttp admin API is always served on.
+	Address string
+
+	// EnableGRPC additionally serves the gRPC admin API described by
+	// pkg/admin/pb. It's off by default: NewGRPCServer/ServeMux only run
+	// when an operator opts in.
+	EnableGRPC bool
+
+	// GRPCAddress, when set alongside EnableGRPC, runs the gRPC admin API
+	// on its own dedicated listener instead of multiplexing it onto
+	// Address via ServeMux.
+	GRPCAddress string
+}
+
+// Start brings up the admin API described by cfg and blocks until it
+// stops. The fasthttp API is always served; the gRPC one is added on top
+// when cfg.EnableGRPC is set.
+func Start(cfg Config) error {
+	l, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.EnableGRPC {
+		return fasthttp.Serve(l, adminHandler)
+	}
+
+	grpcSrv := NewGRPCServer()
+
+	if cfg.GRPCAddress != "" {
+		gl, err := net.Listen("tcp", cfg.GRPCAddress)
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := grpcSrv.Serve(gl); err != nil {
+				log.DefaultLogger.Errorf("Admin gRPC server stopped: %s", err)
+			}
+		}()
+		return fasthttp.Serve(l, adminHandler)
+	}
+
+	return ServeMux(l, grpcSrv, func(hl net.Listener) error {
+		return fasthttp.Serve(hl, adminHandler)
+	})
+}
+
+// adminHandler dispatches the fasthttp admin API's known paths to their
+// handlers in apis.go; everything else 404s.
+func adminHandler(ctx *fasthttp.RequestCtx) {
+	switch string(ctx.Path()) {
+	case "/api/v1/config_dump":
+		configDump(ctx)
+	case "/api/v1/stats":
+		statsDump(ctx)
+	case "/api/v1/log_level":
+		setLogLevel(ctx)
+	case "/api/v1/update_listener_tls":
+		updateListenerTLS(ctx)
+	case "/api/v1/update_listener_websocket":
+		updateListenerWebSocket(ctx)
+	case "/api/v1/update_listener_connect":
+		updateListenerConnect(ctx)
+	default:
+		ctx.SetStatusCode(404)
+	}
+}
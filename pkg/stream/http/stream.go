@@ -21,22 +21,31 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/alipay/sofa-mosn/pkg/buffer"
 	"github.com/alipay/sofa-mosn/pkg/log"
+	"github.com/alipay/sofa-mosn/pkg/metrics"
 	"github.com/alipay/sofa-mosn/pkg/protocol"
 	mosnhttp "github.com/alipay/sofa-mosn/pkg/protocol/http"
 	str "github.com/alipay/sofa-mosn/pkg/stream"
 	"github.com/alipay/sofa-mosn/pkg/types"
+	gometrics "github.com/rcrowley/go-metrics"
 	"github.com/valyala/fasthttp"
 )
 
+// readDisablePollInterval is how often a body-streaming goroutine rechecks
+// readDisableCount while it's paused.
+const readDisablePollInterval = 10 * time.Millisecond
+
 var (
 	errConnClose = errors.New("connection closed")
 
@@ -44,6 +53,186 @@ var (
 	HVKeepAlive  = []byte("keep-alive") // header value 'keep-alive'
 )
 
+var (
+	websocketMu sync.RWMutex
+	// websocketDisabled tracks listeners that had WebSocket tunneling
+	// turned off via the admin API; absent entries default to enabled.
+	websocketDisabled = make(map[string]bool)
+)
+
+// SetWebSocketTunnel enables or disables WebSocket upgrade handling for a
+// given listener at runtime, mirroring how the admin API hot-updates a
+// listener's TLS config without a restart.
+func SetWebSocketTunnel(listener string, enabled bool) {
+	websocketMu.Lock()
+	defer websocketMu.Unlock()
+	websocketDisabled[listener] = !enabled
+}
+
+func websocketTunnelEnabled(listener string) bool {
+	websocketMu.RLock()
+	defer websocketMu.RUnlock()
+	return !websocketDisabled[listener]
+}
+
+// isUpgradeRequest reports whether the request line asks to switch
+// protocols, i.e. it carries "Connection: Upgrade" alongside a concrete
+// "Upgrade" target such as "websocket".
+func isUpgradeRequest(header *fasthttp.RequestHeader) bool {
+	upgrade := header.Peek("Upgrade")
+	if len(upgrade) == 0 {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(header.Peek("Connection"))), "upgrade")
+}
+
+var (
+	connectAllowMu sync.RWMutex
+	// connectAllowlist maps a listener name to the destination host:port
+	// patterns its CONNECT tunnels may dial. An unlisted listener allows
+	// nothing, so CONNECT proxying can't be abused as an open relay unless
+	// an operator opts in through the admin API.
+	connectAllowlist = make(map[string][]string)
+)
+
+// SetConnectAllowlist replaces the set of destination host:port patterns a
+// listener's CONNECT tunnel is allowed to dial, mirroring the admin API's
+// other per-listener hot-updates (TLS, WebSocket tunneling).
+func SetConnectAllowlist(listener string, patterns []string) {
+	connectAllowMu.Lock()
+	defer connectAllowMu.Unlock()
+	connectAllowlist[listener] = patterns
+}
+
+func connectAllowed(listener, authority string) bool {
+	connectAllowMu.RLock()
+	patterns := connectAllowlist[listener]
+	connectAllowMu.RUnlock()
+
+	for _, pattern := range patterns {
+		if matchHostPattern(pattern, authority) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHostPattern supports an exact "host:port" match or a leading "*"
+// wildcard such as "*.example.com:443".
+func matchHostPattern(pattern, authority string) bool {
+	if pattern == authority {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*") {
+		return strings.HasSuffix(authority, pattern[1:])
+	}
+	return false
+}
+
+// ConnectClusterDialer, when set, lets CONNECT tunnels dial their upstream
+// through MOSN's cluster manager instead of a raw net.Dial, so a tunnel
+// gets the same load balancing, health checking, and upstream TLS as every
+// other proxied stream. It is nil by default and nothing in MOSN assigns
+// it, so until a caller sets it every CONNECT tunnel is refused unless its
+// listener has direct dial enabled. When set, it is always tried first; a
+// direct dial only happens if this is nil, or it returns an error, and the
+// listener has opted into the fallback via SetConnectDirectDial.
+var ConnectClusterDialer func(ctx context.Context, authority string) (net.Conn, error)
+
+var (
+	directDialMu  sync.RWMutex
+	directDialSet = make(map[string]bool)
+)
+
+// SetConnectDirectDial opts a listener's CONNECT tunnels into dialing the
+// destination directly when no cluster is configured to route it (or none
+// is registered at all), instead of the default of rejecting the tunnel.
+func SetConnectDirectDial(listener string, allowed bool) {
+	directDialMu.Lock()
+	defer directDialMu.Unlock()
+	directDialSet[listener] = allowed
+}
+
+func connectDirectDialAllowed(listener string) bool {
+	directDialMu.RLock()
+	defer directDialMu.RUnlock()
+	return directDialSet[listener]
+}
+
+var errNoClusterForConnect = errors.New("no cluster available to route CONNECT and direct dial is disabled for this listener")
+
+// dialConnectUpstream opens the CONNECT tunnel's upstream connection,
+// preferring the cluster manager (via ConnectClusterDialer) and only
+// falling back to a direct dial when the listener has explicitly opted in
+// through SetConnectDirectDial.
+func (conn *serverStreamConnection) dialConnectUpstream(authority string) (net.Conn, error) {
+	if ConnectClusterDialer != nil {
+		upstream, err := ConnectClusterDialer(conn.context, authority)
+		if err == nil {
+			return upstream, nil
+		}
+		if !connectDirectDialAllowed(conn.listenerName) {
+			return nil, err
+		}
+	} else if !connectDirectDialAllowed(conn.listenerName) {
+		return nil, errNoClusterForConnect
+	}
+
+	return net.Dial("tcp", authority)
+}
+
+// connectStats are the per-listener CONNECT tunnel counters surfaced
+// through the existing metrics package, so they show up in statsDump
+// alongside every other listener stat.
+type connectStats struct {
+	accepted gometrics.Counter
+	denied   gometrics.Counter
+	active   gometrics.Gauge
+
+	// activeCount backs active: gometrics.Gauge only exposes Value/Update,
+	// not an atomic increment, so a concurrent read-modify-write through
+	// them would race. Adjust this instead and Update active from it.
+	activeCount int64
+}
+
+func (s *connectStats) incActive() {
+	s.active.Update(atomic.AddInt64(&s.activeCount, 1))
+}
+
+func (s *connectStats) decActive() {
+	s.active.Update(atomic.AddInt64(&s.activeCount, -1))
+}
+
+var (
+	connectStatsMu  sync.Mutex
+	connectStatsMap = make(map[string]*connectStats)
+)
+
+func connectStatsFor(listener string) *connectStats {
+	connectStatsMu.Lock()
+	defer connectStatsMu.Unlock()
+
+	if s, ok := connectStatsMap[listener]; ok {
+		return s
+	}
+
+	s := &connectStats{}
+	m, err := metrics.NewMetrics("http1_connect", map[string]string{"listener": listener})
+	if err != nil {
+		log.DefaultLogger.Errorf("http1 CONNECT metrics registration failed for listener %s: %s", listener, err)
+		s.accepted = gometrics.NewCounter()
+		s.denied = gometrics.NewCounter()
+		s.active = gometrics.NewGauge()
+	} else {
+		s.accepted = m.Counter("connect_accepted")
+		s.denied = m.Counter("connect_denied")
+		s.active = m.Gauge("connect_active")
+	}
+
+	connectStatsMap[listener] = s
+	return s
+}
+
 func init() {
 	str.Register(protocol.HTTP1, &streamConnFactory{})
 }
@@ -167,6 +356,10 @@ type clientStreamConnection struct {
 	mutex                         sync.RWMutex
 	connectionEventListener       types.ConnectionEventListener
 	streamConnectionEventListener types.StreamConnectionEventListener
+
+	// tunnel is set once the upstream has switched protocols (101) and the
+	// connection has been handed off to raw byte splicing.
+	tunnel int32
 }
 
 func newClientStreamConnection(context context.Context, connection types.ClientConnection,
@@ -229,6 +422,13 @@ func (conn *clientStreamConnection) serve() {
 			s.handleResponse()
 		}
 
+		// the upstream accepted our upgrade request: stop parsing HTTP
+		// frames and splice raw bytes until either side closes.
+		if atomic.LoadInt32(&conn.tunnel) == 1 {
+			conn.serveTunnel(s)
+			return
+		}
+
 		// local reset
 		if resetConn {
 			// close connection
@@ -238,6 +438,24 @@ func (conn *clientStreamConnection) serve() {
 	}
 }
 
+// serveTunnel relays raw bytes read off the upstream connection straight
+// to the stream's receiver, with no further HTTP framing applied. It runs
+// once a 101 response has switched the connection's mode.
+func (conn *clientStreamConnection) serveTunnel(s *clientStream) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := conn.br.Read(buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(data), false)
+		}
+		if err != nil {
+			s.ResetStream(types.StreamRemoteReset)
+			return
+		}
+	}
+}
+
 func (conn *clientStreamConnection) GoAway() {}
 
 func (conn *clientStreamConnection) NewStream(ctx context.Context, receiver types.StreamReceiveListener) types.StreamSender {
@@ -281,10 +499,20 @@ type serverStreamConnection struct {
 	stream                   *serverStream
 	mutex                    sync.RWMutex
 	serverStreamConnListener types.ServerStreamConnectionEventListener
+
+	// listenerName is used to look up the per-listener WebSocket tunneling
+	// toggle exposed through the admin API.
+	listenerName string
+
+	// tunnel is set once a stream has upgraded the connection to a raw
+	// byte tunnel.
+	tunnel int32
 }
 
 func newServerStreamConnection(context context.Context, connection types.Connection,
 	callbacks types.ServerStreamConnectionEventListener) types.ServerStreamConnection {
+	listenerName, _ := context.Value(types.ContextKeyListenerName).(string)
+
 	ssc := &serverStreamConnection{
 		streamConnection: streamConnection{
 			context: context,
@@ -292,6 +520,7 @@ func newServerStreamConnection(context context.Context, connection types.Connect
 			bufChan: make(chan types.IoBuffer),
 		},
 		serverStreamConnListener: callbacks,
+		listenerName:             listenerName,
 	}
 
 	ssc.br = bufio.NewReader(ssc)
@@ -315,9 +544,14 @@ func newServerStreamConnection(context context.Context, connection types.Connect
 
 func (conn *serverStreamConnection) serve() {
 	for {
-		// 1. blocking read using fasthttp.Request.Read
+		// 1. blocking read of the request line and headers; the body is
+		// left on the wire and streamed in below instead of being
+		// buffered whole by fasthttp up front.
 		request := fasthttp.AcquireRequest()
-		err := request.Read(conn.br)
+		err := request.Header.Read(conn.br)
+		if err == nil {
+			err = request.ContinueReadBodyStream(conn.br, 0)
+		}
 		if err != nil {
 			if conn.stream != nil {
 				conn.stream.ResetStream(types.StreamRemoteReset)
@@ -326,6 +560,14 @@ func (conn *serverStreamConnection) serve() {
 			return
 		}
 
+		// CONNECT bypasses fasthttp and the receiver entirely: it opens a
+		// raw tunnel to the requested authority and then this connection
+		// carries nothing but opaque bytes for its remaining lifetime.
+		if string(request.Header.Method()) == http.MethodConnect {
+			conn.handleConnect(request)
+			return
+		}
+
 		id := protocol.GenerateID()
 		// 2. request processing
 		s := &serverStream{
@@ -337,6 +579,11 @@ func (conn *serverStreamConnection) serve() {
 			},
 			connection:       conn,
 			responseDoneChan: make(chan bool, 1),
+			bodyDone:         make(chan struct{}),
+		}
+
+		if isUpgradeRequest(&request.Header) && websocketTunnelEnabled(conn.listenerName) {
+			s.mode = str.ModeTunnel
 		}
 
 		s.receiver = conn.serverStreamConnListener.NewStreamDetect(s.stream.ctx, s, spanBuilder)
@@ -346,12 +593,106 @@ func (conn *serverStreamConnection) serve() {
 		conn.mutex.Unlock()
 
 		if atomic.LoadInt32(&s.readDisableCount) <= 0 {
-			s.handleRequest()
+			s.startOnce.Do(s.handleRequest)
 		}
 
 		// wait for proxy done
 		<-s.responseDoneChan
+
+		// make sure nothing is still reading the request body off conn.br
+		// before the next loop iteration reads the following request line.
+		<-s.bodyDone
+
+		// the response completed the upgrade handshake: stop parsing HTTP
+		// frames and splice raw bytes between the two sides instead.
+		if atomic.LoadInt32(&conn.tunnel) == 1 {
+			conn.serveTunnel(s)
+			return
+		}
+	}
+}
+
+// serveTunnel relays raw bytes read off the downstream connection straight
+// to the stream's receiver, with no further HTTP framing applied. It runs
+// once a stream has switched this connection into WebSocket tunnel mode.
+func (conn *serverStreamConnection) serveTunnel(s *serverStream) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := conn.br.Read(buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(data), false)
+		}
+		if err != nil {
+			s.ResetStream(types.StreamRemoteReset)
+			return
+		}
+	}
+}
+
+// handleConnect resolves the authority from a CONNECT request line, dials
+// it (subject to the listener's admin-configured allowlist) -- preferring
+// the cluster manager over a direct dial, see dialConnectUpstream -- and
+// on success replies "200 Connection Established" before splicing the two
+// raw byte streams together. It owns the connection for as long as the
+// tunnel is open; there is no going back to HTTP framing.
+func (conn *serverStreamConnection) handleConnect(request *fasthttp.Request) {
+	authority := string(request.Header.RequestURI())
+	if authority == "" {
+		authority = string(request.Header.Host())
+	}
+
+	stats := connectStatsFor(conn.listenerName)
+
+	if !connectAllowed(conn.listenerName, authority) {
+		stats.denied.Inc(1)
+		log.DefaultLogger.Errorf("CONNECT to %s denied: not in listener %s's allowlist", authority, conn.listenerName)
+		conn.bw.WriteString("HTTP/1.1 403 Forbidden\r\n\r\n")
+		conn.bw.Flush()
+		return
+	}
+
+	upstream, err := conn.dialConnectUpstream(authority)
+	if err != nil {
+		log.DefaultLogger.Errorf("CONNECT to %s failed: %s", authority, err)
+		conn.bw.WriteString("HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		conn.bw.Flush()
+		return
 	}
+	defer upstream.Close()
+
+	if _, err := conn.bw.WriteString("HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		return
+	}
+	if err := conn.bw.Flush(); err != nil {
+		return
+	}
+
+	stats.accepted.Inc(1)
+	stats.incActive()
+	defer stats.decActive()
+
+	pipe(conn.br, conn, upstream)
+}
+
+// pipe bidirectionally relays raw bytes between a downstream HTTP
+// connection and an upstream CONNECT target until either side closes,
+// bypassing fasthttp completely in both directions.
+func pipe(downstreamReader *bufio.Reader, downstreamWriter io.Writer, upstream net.Conn) {
+	done := make(chan struct{}, 2)
+
+	go func() {
+		io.Copy(upstream, downstreamReader)
+		upstream.Close()
+		done <- struct{}{}
+	}()
+
+	go func() {
+		io.Copy(downstreamWriter, upstream)
+		done <- struct{}{}
+	}()
+
+	<-done
 }
 
 func (conn *serverStreamConnection) ActiveStreamsNum() int {
@@ -378,6 +719,20 @@ type stream struct {
 	readDisableCount int32
 	ctx              context.Context
 
+	// mode is str.ModeRequest for ordinary request/response traffic, or
+	// str.ModeTunnel once the stream has upgraded its connection (e.g.
+	// WebSocket) into a raw byte splice.
+	mode str.Mode
+
+	// bodyWriter, when non-nil, is the write end of the io.Pipe feeding
+	// the fasthttp body stream set up by AppendHeaders; AppendData writes
+	// each chunk straight into it instead of buffering the full body.
+	// sendDone is closed once the goroutine draining the other end of the
+	// pipe onto the wire returns, so endStream knows it's safe to recycle
+	// the request/response.
+	bodyWriter *io.PipeWriter
+	sendDone   chan struct{}
+
 	// NOTICE: fasthttp ctx and its member not allowed holding by others after request handle finished
 	request  *fasthttp.Request
 	response *fasthttp.Response
@@ -401,6 +756,10 @@ type clientStream struct {
 func (s *clientStream) AppendHeaders(context context.Context, headersIn types.HeaderMap, endStream bool) error {
 	headers := headersIn.(mosnhttp.RequestHeader)
 
+	if upgrade, ok := headers.Get("Upgrade"); ok && upgrade != "" {
+		s.mode = str.ModeTunnel
+	}
+
 	// TODO: protocol convert in pkg/protocol
 	//if the request contains body, use "POST" as default, the http request method will be setted by MosnHeaderMethod
 	if endStream {
@@ -416,13 +775,40 @@ func (s *clientStream) AppendHeaders(context context.Context, headersIn types.He
 
 	if endStream {
 		s.endStream()
+	} else if s.mode != str.ModeTunnel {
+		// stream the body out as AppendData delivers it instead of
+		// buffering the whole request in memory before sending anything;
+		// doSend blocks reading from pr as it writes the request, so it
+		// has to run concurrently with the AppendData calls that feed pw.
+		pr, pw := io.Pipe()
+		s.bodyWriter = pw
+		s.sendDone = make(chan struct{})
+		s.request.SetBodyStream(pr, -1)
+
+		go func() {
+			defer close(s.sendDone)
+			s.doSend()
+		}()
 	}
 
 	return nil
 }
 
 func (s *clientStream) AppendData(context context.Context, data types.IoBuffer, endStream bool) error {
-	s.request.SetBody(data.Bytes())
+	// once the upstream has switched protocols, data frames are raw bytes
+	// that must be relayed verbatim rather than buffered as a body.
+	if atomic.LoadInt32(&s.connection.tunnel) == 1 {
+		_, err := s.connection.Write(data.Bytes())
+		return err
+	}
+
+	if s.bodyWriter != nil {
+		if _, err := s.bodyWriter.Write(data.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		s.request.SetBody(data.Bytes())
+	}
 
 	if endStream {
 		s.endStream()
@@ -437,6 +823,15 @@ func (s *clientStream) AppendTrailers(context context.Context, trailers types.He
 }
 
 func (s *clientStream) endStream() {
+	if s.bodyWriter != nil {
+		// closing the pipe writer is what unblocks doSend's WriteTo with
+		// an EOF on the body stream; wait for it so the request/response
+		// aren't recycled out from under an in-flight write.
+		s.bodyWriter.Close()
+		<-s.sendDone
+		return
+	}
+
 	s.doSend()
 }
 
@@ -469,6 +864,14 @@ func (s *clientStream) handleResponse() {
 
 		log.DefaultLogger.Debugf("remote:%s, status:%s", s.connection.conn.RemoteAddr(), status)
 
+		// the upstream accepted our upgrade: tear down HTTP framing on
+		// this connection and relay raw bytes from here on.
+		if s.mode == str.ModeTunnel && statusCode == http.StatusSwitchingProtocols {
+			s.receiver.OnReceiveHeaders(s.ctx, header, false)
+			atomic.StoreInt32(&s.connection.tunnel, 1)
+			return
+		}
+
 		hasData := true
 		if len(s.response.Body()) == 0 {
 			hasData = false
@@ -500,6 +903,17 @@ type serverStream struct {
 
 	connection       *serverStreamConnection
 	responseDoneChan chan bool
+
+	// bodyDone is closed once the request body has been fully streamed in
+	// (or skipped), so the connection's serve loop knows it's safe to read
+	// the next request off the same bufio.Reader.
+	bodyDone chan struct{}
+
+	// startOnce guards handleRequest: ReadDisable(false) calls it whenever
+	// readDisableCount drops to zero, which happens once per pause/resume
+	// cycle, but the request must only be handled (and bodyDone only
+	// closed) the first time it stops being disabled.
+	startOnce sync.Once
 }
 
 // types.StreamSender
@@ -531,15 +945,45 @@ func (s *serverStream) AppendHeaders(context context.Context, headersIn types.He
 		headers.CopyTo(&s.response.Header)
 	}
 
-	if endStream {
+	if endStream || s.tunneling() {
+		// a 101 ends the HTTP exchange here: endStream sends it and hands
+		// the connection off to serveTunnel before any raw data is relayed.
 		s.endStream()
+	} else {
+		// stream the response body out as AppendData delivers it instead
+		// of buffering the whole thing before writing the first byte;
+		// doSend blocks reading from pr as it writes the response, so it
+		// has to run concurrently with the AppendData calls that feed pw.
+		pr, pw := io.Pipe()
+		s.bodyWriter = pw
+		s.sendDone = make(chan struct{})
+		s.response.SetBodyStream(pr, -1)
+
+		go func() {
+			defer close(s.sendDone)
+			s.doSend()
+		}()
 	}
 
 	return nil
 }
 
 func (s *serverStream) AppendData(context context.Context, data types.IoBuffer, endStream bool) error {
-	s.response.SetBody(data.Bytes())
+	// once the connection has been handed off to a raw tunnel, data frames
+	// are bytes relayed straight from the upstream side and must bypass
+	// fasthttp's response body entirely.
+	if s.tunneling() {
+		_, err := s.connection.Write(data.Bytes())
+		return err
+	}
+
+	if s.bodyWriter != nil {
+		if _, err := s.bodyWriter.Write(data.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		s.response.SetBody(data.Bytes())
+	}
 
 	if endStream {
 		s.endStream()
@@ -553,7 +997,24 @@ func (s *serverStream) AppendTrailers(context context.Context, trailers types.He
 	return nil
 }
 
+// tunneling reports whether the response actually completed an upgrade
+// handshake -- s.mode alone only records that the downstream request
+// asked for one, which the upstream is still free to reject.
+func (s *serverStream) tunneling() bool {
+	return s.mode == str.ModeTunnel && s.response.StatusCode() == http.StatusSwitchingProtocols
+}
+
 func (s *serverStream) endStream() {
+	// a successful upgrade response (101) switches the whole connection
+	// into a raw byte tunnel: send it and hand off to serveTunnel instead
+	// of the keep-alive/close and fasthttp recycle bookkeeping below.
+	if s.tunneling() {
+		s.doSend()
+		atomic.StoreInt32(&s.connection.tunnel, 1)
+		s.responseDoneChan <- true
+		return
+	}
+
 	resetConn := false
 	// check if we need close connection
 	if s.request.Header.ConnectionClose() {
@@ -567,7 +1028,15 @@ func (s *serverStream) endStream() {
 	}
 	defer s.DestroyStream()
 
-	s.doSend()
+	if s.bodyWriter != nil {
+		// closing the pipe writer is what unblocks doSend's WriteTo with
+		// an EOF on the body stream; wait for it so the request/response
+		// aren't recycled out from under an in-flight write.
+		s.bodyWriter.Close()
+		<-s.sendDone
+	} else {
+		s.doSend()
+	}
 	s.responseDoneChan <- true
 
 	if resetConn {
@@ -595,7 +1064,7 @@ func (s *serverStream) ReadDisable(disable bool) {
 		newCount := atomic.AddInt32(&s.readDisableCount, -1)
 
 		if newCount <= 0 {
-			s.handleRequest()
+			s.startOnce.Do(s.handleRequest)
 		}
 	}
 }
@@ -613,14 +1082,54 @@ func (s *serverStream) handleRequest() {
 		// set non-header info in request-line, like method, uri
 		injectInternalHeaders(header, s.request.URI())
 
-		hasData := true
-		if len(s.request.Body()) == 0 {
-			hasData = false
+		noBody := s.request.Header.ContentLength() == 0
+		s.receiver.OnReceiveHeaders(s.ctx, header, noBody)
+
+		if noBody {
+			close(s.bodyDone)
+			return
 		}
-		s.receiver.OnReceiveHeaders(s.ctx, header, !hasData)
 
-		if hasData {
-			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(s.request.Body()), true)
+		// stream the body in off the wire as it arrives instead of
+		// waiting for fasthttp to buffer the whole thing first; this is
+		// what lets large uploads and SSE-style long-lived request bodies
+		// proxy without OOMing.
+		go func() {
+			defer close(s.bodyDone)
+			s.streamRequestBody()
+		}()
+	}
+}
+
+// streamRequestBody reads the request body in chunks from the stream
+// fasthttp left on the wire (set up by ContinueReadBodyStream), handing
+// each chunk to the receiver as it arrives. It pauses between reads while
+// readDisableCount is positive, the same back-pressure signal ReadDisable
+// uses everywhere else in this codec.
+func (s *serverStream) streamRequestBody() {
+	body := s.request.BodyStream()
+	if body == nil {
+		s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(nil), true)
+		return
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		for atomic.LoadInt32(&s.readDisableCount) > 0 {
+			time.Sleep(readDisablePollInterval)
+		}
+
+		n, err := body.Read(buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(data), false)
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.DefaultLogger.Errorf("http1 server stream read request body error: %s", err)
+			}
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(nil), true)
+			return
 		}
 	}
 }
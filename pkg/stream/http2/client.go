@@ -0,0 +1,321 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http2
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alipay/sofa-mosn/pkg/buffer"
+	"github.com/alipay/sofa-mosn/pkg/log"
+	"github.com/alipay/sofa-mosn/pkg/types"
+	"golang.org/x/net/http2"
+)
+
+// types.ClientStreamConnection
+type clientStreamConnection struct {
+	streamConnection
+
+	// nextStreamID is the next client-initiated stream ID to hand out.
+	// Per RFC 7540 Section 5.1.1 client-initiated streams use odd IDs
+	// starting at 1.
+	nextStreamID uint32
+
+	mutex                         sync.RWMutex
+	streams                       map[uint32]*clientStream
+	connectionEventListener       types.ConnectionEventListener
+	streamConnectionEventListener types.StreamConnectionEventListener
+}
+
+func newClientStreamConnection(context context.Context, connection types.ClientConnection,
+	streamConnCallbacks types.StreamConnectionEventListener,
+	connCallbacks types.ConnectionEventListener) types.ClientStreamConnection {
+
+	csc := &clientStreamConnection{
+		streamConnection:              newStreamConnection(context, connection),
+		nextStreamID:                  1,
+		streams:                       make(map[uint32]*clientStream),
+		connectionEventListener:       connCallbacks,
+		streamConnectionEventListener: streamConnCallbacks,
+	}
+
+	if err := csc.framer.WriteSettings(); err != nil {
+		log.DefaultLogger.Errorf("http2 client write initial SETTINGS error: %s", err)
+	}
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				log.DefaultLogger.Errorf("http2 client serve goroutine panic %v", p)
+				debug.PrintStack()
+
+				csc.serve()
+			}
+		}()
+
+		csc.serve()
+	}()
+
+	return csc
+}
+
+func (conn *clientStreamConnection) serve() {
+	for {
+		frame, err := conn.framer.ReadFrame()
+		if err != nil {
+			conn.resetAllStreams(types.StreamConnectionFailed)
+			log.DefaultLogger.Errorf("http2 client codec goroutine error: %s", err)
+			return
+		}
+
+		switch f := frame.(type) {
+		case *http2.MetaHeadersFrame:
+			conn.handleHeaders(f)
+		case *http2.DataFrame:
+			conn.handleData(f)
+		case *http2.WindowUpdateFrame:
+			conn.handleWindowUpdate(f)
+		case *http2.GoAwayFrame:
+			conn.resetAllStreams(types.StreamRemoteReset)
+			return
+		case *http2.RSTStreamFrame:
+			conn.handleReset(f.StreamID)
+		case *http2.SettingsFrame:
+			conn.handleSettings(f)
+		case *http2.PingFrame:
+			conn.handlePing(f)
+		}
+	}
+}
+
+func (conn *clientStreamConnection) getStream(id uint32) *clientStream {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return conn.streams[id]
+}
+
+func (conn *clientStreamConnection) handleHeaders(f *http2.MetaHeadersFrame) {
+	s := conn.getStream(f.StreamID)
+	if s == nil {
+		return
+	}
+
+	h := make(header, len(f.Fields))
+	for _, hf := range f.Fields {
+		if hf.Name == ":status" {
+			h.Set(types.HeaderStatus, hf.Value)
+			continue
+		}
+		h.Set(hf.Name, hf.Value)
+	}
+
+	endStream := f.StreamEnded()
+	s.deliverOrQueue(func() {
+		s.receiver.OnReceiveHeaders(s.ctx, h, endStream)
+	})
+
+	if endStream && s.markRemoteClosed() {
+		conn.endStream(f.StreamID)
+	}
+}
+
+func (conn *clientStreamConnection) handleData(f *http2.DataFrame) {
+	s := conn.getStream(f.StreamID)
+	if s == nil {
+		return
+	}
+
+	data := append([]byte(nil), f.Data()...)
+	endStream := f.StreamEnded()
+
+	// Connection-level flow-control credit is shared by every multiplexed
+	// stream, so it's replenished as soon as the bytes are off the wire,
+	// regardless of whether this particular stream is paused -- otherwise
+	// one ReadDisable'd stream would starve every other stream on the
+	// connection once the shared window ran out.
+	if len(data) > 0 {
+		conn.writeFrame(func() error {
+			return conn.framer.WriteWindowUpdate(0, uint32(len(data)))
+		})
+	}
+
+	if len(data) > 0 {
+		s.deliverOrQueue(func() {
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(data), endStream)
+
+			// the per-stream window, unlike the connection-level one, is
+			// only replenished once this stream is actually ready for the
+			// data, so a paused stream's peer still sees backpressure.
+			conn.writeFrame(func() error {
+				return conn.framer.WriteWindowUpdate(f.StreamID, uint32(len(data)))
+			})
+		})
+	} else if endStream {
+		s.deliverOrQueue(func() {
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(nil), true)
+		})
+	}
+
+	if endStream && s.markRemoteClosed() {
+		conn.endStream(f.StreamID)
+	}
+}
+
+func (conn *clientStreamConnection) handleWindowUpdate(f *http2.WindowUpdateFrame) {
+	if f.StreamID == 0 {
+		conn.flowMu.Lock()
+		conn.connSendWindow += f.Increment
+		conn.flowMu.Unlock()
+		conn.flowCond.Broadcast()
+		return
+	}
+	if s := conn.getStream(f.StreamID); s != nil {
+		conn.flowMu.Lock()
+		atomic.AddUint32(&s.sendWindow, f.Increment)
+		conn.flowMu.Unlock()
+		conn.flowCond.Broadcast()
+	}
+}
+
+func (conn *clientStreamConnection) handleReset(id uint32) {
+	if s := conn.getStream(id); s != nil {
+		s.ResetStream(types.StreamRemoteReset)
+	}
+	conn.endStream(id)
+}
+
+func (conn *clientStreamConnection) endStream(id uint32) {
+	conn.mutex.Lock()
+	delete(conn.streams, id)
+	conn.mutex.Unlock()
+}
+
+func (conn *clientStreamConnection) resetAllStreams(reason types.StreamResetReason) {
+	conn.mutex.Lock()
+	streams := conn.streams
+	conn.streams = make(map[uint32]*clientStream)
+	conn.mutex.Unlock()
+
+	for _, s := range streams {
+		s.ResetStream(reason)
+	}
+}
+
+// NewStream allocates a fresh client-initiated stream ID, per RFC 7540
+// Section 5.1.1: odd numbered, monotonically increasing for the lifetime
+// of the connection.
+func (conn *clientStreamConnection) NewStream(ctx context.Context, receiver types.StreamReceiveListener) types.StreamSender {
+	id := atomic.AddUint32(&conn.nextStreamID, 2) - 2
+
+	s := &clientStream{
+		stream: stream{
+			id:         uint64(id),
+			streamID:   id,
+			ctx:        context.WithValue(ctx, types.ContextKeyStreamID, id),
+			receiver:   receiver,
+			sendWindow: initialWindowSize,
+		},
+		connection: conn,
+	}
+
+	conn.mutex.Lock()
+	conn.streams[id] = s
+	atomic.StoreUint32(&conn.lastStreamID, id)
+	conn.mutex.Unlock()
+
+	return s
+}
+
+// ActiveStreamsNum reports the number of concurrently in-flight streams,
+// as opposed to the HTTP/1.1 codec which only ever tracks a single
+// in-flight stream at a time.
+func (conn *clientStreamConnection) ActiveStreamsNum() int {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return len(conn.streams)
+}
+
+func (conn *clientStreamConnection) Reset(reason types.StreamResetReason) {
+	conn.resetAllStreams(reason)
+	conn.closeFlowControl()
+	close(conn.bufChan)
+}
+
+// types.StreamSender for request
+type clientStream struct {
+	stream
+
+	connection *clientStreamConnection
+}
+
+func (s *clientStream) AppendHeaders(context context.Context, headersIn types.HeaderMap, endStream bool) error {
+	err := s.connection.writeHeaders(s.streamID, headersIn, endStream)
+
+	if endStream {
+		s.endStream()
+	}
+	return err
+}
+
+func (s *clientStream) AppendData(context context.Context, data types.IoBuffer, endStream bool) error {
+	err := s.connection.writeDataFlowControlled(&s.stream, data.Bytes(), endStream)
+
+	if endStream {
+		s.endStream()
+	}
+	return err
+}
+
+// AppendTrailers encodes the trailers as their own HEADERS frame with
+// END_STREAM set, per RFC 7540 Section 8.1 -- trailers are not folded
+// into the preceding DATA frame.
+func (s *clientStream) AppendTrailers(context context.Context, trailers types.HeaderMap) error {
+	err := s.connection.writeHeaders(s.streamID, trailers, true)
+
+	s.endStream()
+	return err
+}
+
+func (s *clientStream) endStream() {
+	if s.markLocalClosed() {
+		s.connection.endStream(s.streamID)
+	}
+}
+
+// ReadDisable maps onto HTTP/2 flow control: while disabled, HEADERS/DATA
+// frames that arrive are buffered rather than delivered, and we simply
+// stop sending WINDOW_UPDATE frames for this stream, which makes the peer
+// stall once its send window for the stream is exhausted -- the same
+// back-pressure effect readDisableCount has on the raw connection in the
+// HTTP/1.1 codec. Re-enabling flushes whatever was buffered, in order.
+func (s *clientStream) ReadDisable(disable bool) {
+	if disable {
+		atomic.AddInt32(&s.readDisableCount, 1)
+		return
+	}
+
+	if atomic.AddInt32(&s.readDisableCount, -1) <= 0 {
+		s.flushPending()
+	}
+}
+
+func (s *clientStream) GetStream() types.Stream {
+	return s
+}
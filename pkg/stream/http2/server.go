@@ -0,0 +1,328 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http2
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alipay/sofa-mosn/pkg/buffer"
+	"github.com/alipay/sofa-mosn/pkg/log"
+	"github.com/alipay/sofa-mosn/pkg/types"
+	"golang.org/x/net/http2"
+)
+
+// types.ServerStreamConnection
+type serverStreamConnection struct {
+	streamConnection
+
+	mutex                    sync.RWMutex
+	streams                  map[uint32]*serverStream
+	serverStreamConnListener types.ServerStreamConnectionEventListener
+}
+
+func newServerStreamConnection(context context.Context, connection types.Connection,
+	callbacks types.ServerStreamConnectionEventListener) types.ServerStreamConnection {
+
+	ssc := &serverStreamConnection{
+		streamConnection:         newStreamConnection(context, connection),
+		streams:                  make(map[uint32]*serverStream),
+		serverStreamConnListener: callbacks,
+	}
+
+	if err := ssc.framer.WriteSettings(); err != nil {
+		log.DefaultLogger.Errorf("http2 server write initial SETTINGS error: %s", err)
+	}
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				log.DefaultLogger.Errorf("http2 server serve goroutine panic %v", p)
+				debug.PrintStack()
+
+				ssc.serve()
+			}
+		}()
+
+		ssc.serve()
+	}()
+
+	return ssc
+}
+
+func (conn *serverStreamConnection) serve() {
+	for {
+		frame, err := conn.framer.ReadFrame()
+		if err != nil {
+			conn.resetAllStreams(types.StreamConnectionFailed)
+			log.DefaultLogger.Errorf("http2 server codec goroutine error: %s", err)
+			return
+		}
+
+		switch f := frame.(type) {
+		case *http2.MetaHeadersFrame:
+			conn.handleHeaders(f)
+		case *http2.DataFrame:
+			conn.handleData(f)
+		case *http2.WindowUpdateFrame:
+			conn.handleWindowUpdate(f)
+		case *http2.RSTStreamFrame:
+			conn.handleReset(f.StreamID)
+		case *http2.GoAwayFrame:
+			conn.resetAllStreams(types.StreamRemoteReset)
+			return
+		case *http2.SettingsFrame:
+			conn.handleSettings(f)
+		case *http2.PingFrame:
+			conn.handlePing(f)
+		}
+	}
+}
+
+func (conn *serverStreamConnection) getStream(id uint32) *serverStream {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return conn.streams[id]
+}
+
+func (conn *serverStreamConnection) handleHeaders(f *http2.MetaHeadersFrame) {
+	h := make(header, len(f.Fields))
+	for _, hf := range f.Fields {
+		switch hf.Name {
+		case ":method":
+			h.Set(types.HeaderMethod, hf.Value)
+		case ":path":
+			h.Set(types.HeaderPath, hf.Value)
+		case ":authority":
+			h.Set(types.HeaderHost, hf.Value)
+		default:
+			h.Set(hf.Name, hf.Value)
+		}
+	}
+
+	id := f.StreamID
+	endStream := f.StreamEnded()
+
+	// trailing HEADERS on a request whose stream is already open: route to
+	// the existing receiver instead of treating it as a second, unrelated
+	// request, which would orphan the in-flight stream mid-transfer.
+	if s := conn.getStream(id); s != nil {
+		s.deliverOrQueue(func() {
+			s.receiver.OnReceiveHeaders(s.ctx, h, endStream)
+		})
+		if endStream && s.markRemoteClosed() {
+			conn.endStream(id)
+		}
+		return
+	}
+
+	s := &serverStream{
+		stream: stream{
+			id:         uint64(id),
+			streamID:   id,
+			ctx:        context.WithValue(conn.context, types.ContextKeyStreamID, id),
+			sendWindow: initialWindowSize,
+		},
+		connection: conn,
+	}
+	s.receiver = conn.serverStreamConnListener.NewStreamDetect(s.ctx, s, nil)
+
+	conn.mutex.Lock()
+	conn.streams[id] = s
+	atomic.StoreUint32(&conn.lastStreamID, id)
+	conn.mutex.Unlock()
+
+	s.receiver.OnReceiveHeaders(s.ctx, h, endStream)
+
+	if endStream && s.markRemoteClosed() {
+		conn.endStream(id)
+	}
+}
+
+func (conn *serverStreamConnection) handleData(f *http2.DataFrame) {
+	s := conn.getStream(f.StreamID)
+	if s == nil {
+		return
+	}
+
+	data := append([]byte(nil), f.Data()...)
+	endStream := f.StreamEnded()
+
+	// Connection-level flow-control credit is shared by every multiplexed
+	// stream, so it's replenished as soon as the bytes are off the wire,
+	// regardless of whether this particular stream is paused -- otherwise
+	// one ReadDisable'd stream would starve every other stream on the
+	// connection once the shared window ran out.
+	if len(data) > 0 {
+		conn.writeFrame(func() error {
+			return conn.framer.WriteWindowUpdate(0, uint32(len(data)))
+		})
+	}
+
+	if len(data) > 0 {
+		s.deliverOrQueue(func() {
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(data), endStream)
+
+			// the per-stream window, unlike the connection-level one, is
+			// only replenished once this stream is actually ready for the
+			// data, so a paused stream's peer still sees backpressure.
+			conn.writeFrame(func() error {
+				return conn.framer.WriteWindowUpdate(f.StreamID, uint32(len(data)))
+			})
+		})
+	} else if endStream {
+		s.deliverOrQueue(func() {
+			s.receiver.OnReceiveData(s.ctx, buffer.NewIoBufferBytes(nil), true)
+		})
+	}
+
+	if endStream && s.markRemoteClosed() {
+		conn.endStream(f.StreamID)
+	}
+}
+
+func (conn *serverStreamConnection) handleWindowUpdate(f *http2.WindowUpdateFrame) {
+	if f.StreamID == 0 {
+		conn.flowMu.Lock()
+		conn.connSendWindow += f.Increment
+		conn.flowMu.Unlock()
+		conn.flowCond.Broadcast()
+		return
+	}
+	if s := conn.getStream(f.StreamID); s != nil {
+		conn.flowMu.Lock()
+		atomic.AddUint32(&s.sendWindow, f.Increment)
+		conn.flowMu.Unlock()
+		conn.flowCond.Broadcast()
+	}
+}
+
+func (conn *serverStreamConnection) handleReset(id uint32) {
+	if s := conn.getStream(id); s != nil {
+		s.ResetStream(types.StreamRemoteReset)
+	}
+	conn.endStream(id)
+}
+
+func (conn *serverStreamConnection) endStream(id uint32) {
+	conn.mutex.Lock()
+	delete(conn.streams, id)
+	conn.mutex.Unlock()
+}
+
+func (conn *serverStreamConnection) resetAllStreams(reason types.StreamResetReason) {
+	conn.mutex.Lock()
+	streams := conn.streams
+	conn.streams = make(map[uint32]*serverStream)
+	conn.mutex.Unlock()
+
+	for _, s := range streams {
+		s.ResetStream(reason)
+	}
+}
+
+// ActiveStreamsNum reports the number of concurrently in-flight streams.
+// HTTP/2 multiplexes many requests onto a single connection, so unlike
+// the HTTP/1.1 codec this is rarely 0 or 1.
+func (conn *serverStreamConnection) ActiveStreamsNum() int {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return len(conn.streams)
+}
+
+func (conn *serverStreamConnection) Reset(reason types.StreamResetReason) {
+	conn.resetAllStreams(reason)
+	conn.closeFlowControl()
+	close(conn.bufChan)
+}
+
+// types.StreamSender for response
+type serverStream struct {
+	stream
+
+	connection *serverStreamConnection
+}
+
+func (s *serverStream) AppendHeaders(context context.Context, headersIn types.HeaderMap, endStream bool) error {
+	status := "200"
+	if v, ok := headersIn.Get(types.HeaderStatus); ok {
+		status = v
+		headersIn.Del(types.HeaderStatus)
+	}
+
+	h := make(header)
+	h.Set(":status", status)
+	headersIn.Range(func(key, value string) bool {
+		h.Set(key, value)
+		return true
+	})
+
+	err := s.connection.writeHeaders(s.streamID, h, endStream)
+
+	if endStream {
+		s.endStream()
+	}
+	return err
+}
+
+func (s *serverStream) AppendData(context context.Context, data types.IoBuffer, endStream bool) error {
+	err := s.connection.writeDataFlowControlled(&s.stream, data.Bytes(), endStream)
+
+	if endStream {
+		s.endStream()
+	}
+	return err
+}
+
+// AppendTrailers sends a trailing HEADERS frame with END_STREAM, rather
+// than silently dropping the trailers by merely ending the stream.
+func (s *serverStream) AppendTrailers(context context.Context, trailers types.HeaderMap) error {
+	err := s.connection.writeHeaders(s.streamID, trailers, true)
+
+	s.endStream()
+	return err
+}
+
+func (s *serverStream) endStream() {
+	if s.markLocalClosed() {
+		defer s.DestroyStream()
+		s.connection.endStream(s.streamID)
+	}
+}
+
+// ReadDisable maps onto HTTP/2 flow control the same way the client stream
+// does: pausing WINDOW_UPDATE replenishment applies back-pressure to the
+// peer without having to stop reading frames for every other multiplexed
+// stream on the connection. DATA frames that arrive while paused are
+// buffered and delivered in order once re-enabled, instead of being lost.
+func (s *serverStream) ReadDisable(disable bool) {
+	if disable {
+		atomic.AddInt32(&s.readDisableCount, 1)
+		return
+	}
+
+	if atomic.AddInt32(&s.readDisableCount, -1) <= 0 {
+		s.flushPending()
+	}
+}
+
+func (s *serverStream) GetStream() types.Stream {
+	return s
+}
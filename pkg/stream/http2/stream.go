@@ -0,0 +1,450 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package http2 implements the HTTP/2 stream codec, covering both h2c
+// (cleartext, upgrade-less as used inside the mesh) and ALPN-negotiated
+// h2 over TLS listeners.
+package http2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alipay/sofa-mosn/pkg/buffer"
+	"github.com/alipay/sofa-mosn/pkg/log"
+	"github.com/alipay/sofa-mosn/pkg/protocol"
+	str "github.com/alipay/sofa-mosn/pkg/stream"
+	"github.com/alipay/sofa-mosn/pkg/types"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+var (
+	errConnClose = errors.New("connection closed")
+
+	// initialWindowSize is the per-stream and per-connection flow-control
+	// window MOSN advertises to its peer, mirroring net/http2's default.
+	initialWindowSize uint32 = 65535
+)
+
+func init() {
+	str.Register(protocol.HTTP2, &streamConnFactory{})
+}
+
+type streamConnFactory struct{}
+
+func (f *streamConnFactory) CreateClientStream(context context.Context, connection types.ClientConnection,
+	streamConnCallbacks types.StreamConnectionEventListener, connCallbacks types.ConnectionEventListener) types.ClientStreamConnection {
+	return newClientStreamConnection(context, connection, streamConnCallbacks, connCallbacks)
+}
+
+func (f *streamConnFactory) CreateServerStream(context context.Context, connection types.Connection,
+	callbacks types.ServerStreamConnectionEventListener) types.ServerStreamConnection {
+	return newServerStreamConnection(context, connection, callbacks)
+}
+
+func (f *streamConnFactory) CreateBiDirectStream(context context.Context, connection types.ClientConnection,
+	clientCallbacks types.StreamConnectionEventListener,
+	serverCallbacks types.ServerStreamConnectionEventListener) types.ClientStreamConnection {
+	return nil
+}
+
+// ProtocolMatch recognises the HTTP/2 connection preface. The preface is
+// sent by the client regardless of whether h2 was negotiated via ALPN on a
+// TLS listener or the connection is plain h2c, so a single check covers
+// both cases described in RFC 7540 Section 3.
+func (f *streamConnFactory) ProtocolMatch(prot string, magic []byte) error {
+	preface := http2.ClientPreface
+	size := len(magic)
+	if size > len(preface) {
+		size = len(preface)
+	}
+
+	if string(magic[:size]) != preface[:size] {
+		return str.FAILED
+	}
+	if size < len(preface) {
+		return str.EAGAIN
+	}
+	return nil
+}
+
+// streamConnection bridges the push-based types.Connection.Dispatch model
+// onto the blocking io.Reader/io.Writer that http2.Framer expects, the same
+// bufChan trick used by the HTTP/1.1 codec in pkg/stream/http.
+type streamConnection struct {
+	context context.Context
+
+	conn              types.Connection
+	connEventListener types.ConnectionEventListener
+
+	bufChan chan types.IoBuffer
+
+	framer *http2.Framer
+	henc   *hpack.Encoder
+	hbuf   bytes.Buffer
+
+	writeMutex sync.Mutex
+
+	goAway       int32
+	lastStreamID uint32
+
+	// flowMu/flowCond guard connSendWindow and, together with each
+	// stream's sendWindow, let AppendData block until the peer has
+	// actually granted enough window instead of writing past what RFC
+	// 7540 Section 6.9 allows. WINDOW_UPDATE frames with StreamID 0
+	// replenish connSendWindow; broadcast wakes any stream waiting on it.
+	flowMu         sync.Mutex
+	flowCond       *sync.Cond
+	connSendWindow uint32
+	closed         int32
+
+	logger log.Logger
+}
+
+func newStreamConnection(context context.Context, conn types.Connection) streamConnection {
+	sc := streamConnection{
+		context:        context,
+		conn:           conn,
+		bufChan:        make(chan types.IoBuffer),
+		connSendWindow: initialWindowSize,
+		logger:         log.DefaultLogger,
+	}
+	sc.flowCond = sync.NewCond(&sc.flowMu)
+
+	sc.henc = hpack.NewEncoder(&sc.hbuf)
+	sc.framer = http2.NewFramer(&sc, &sc)
+	sc.framer.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+	sc.framer.SetMaxReadFrameSize(16384)
+
+	return sc
+}
+
+// types.StreamConnection
+func (conn *streamConnection) Dispatch(buffer types.IoBuffer) {
+	for buffer.Len() > 0 {
+		conn.bufChan <- buffer
+		<-conn.bufChan
+	}
+}
+
+func (conn *streamConnection) Protocol() types.Protocol {
+	return protocol.HTTP2
+}
+
+// GoAway sends a real GOAWAY frame carrying the highest stream ID MOSN has
+// processed, telling the peer no new streams will be accepted past it.
+func (conn *streamConnection) GoAway() {
+	if !atomic.CompareAndSwapInt32(&conn.goAway, 0, 1) {
+		return
+	}
+
+	conn.writeMutex.Lock()
+	defer conn.writeMutex.Unlock()
+
+	lastStreamID := atomic.LoadUint32(&conn.lastStreamID)
+	if err := conn.framer.WriteGoAway(lastStreamID, http2.ErrCodeNo, nil); err != nil {
+		conn.logger.Errorf("http2 write GOAWAY error: %s", err)
+	}
+}
+
+func (conn *streamConnection) Read(p []byte) (n int, err error) {
+	data, ok := <-conn.bufChan
+
+	if !ok {
+		err = errConnClose
+		return
+	}
+
+	n = copy(p, data.Bytes())
+	data.Drain(n)
+	conn.bufChan <- nil
+	return
+}
+
+func (conn *streamConnection) Write(p []byte) (n int, err error) {
+	n = len(p)
+
+	// TODO avoid copy
+	buf := buffer.GetIoBuffer(n)
+	buf.Write(p)
+
+	err = conn.conn.Write(buf)
+	return
+}
+
+func (conn *streamConnection) writeFrame(write func() error) error {
+	conn.writeMutex.Lock()
+	defer conn.writeMutex.Unlock()
+	return write()
+}
+
+// handleSettings ACKs a peer SETTINGS frame per RFC 7540 Section 6.5.3.
+// http2.Framer.ReadFrame is a bare frame reader -- unlike http2.Server or
+// http2.Transport, it never does this for us.
+func (conn *streamConnection) handleSettings(f *http2.SettingsFrame) {
+	if f.IsAck() {
+		return
+	}
+	if err := conn.writeFrame(conn.framer.WriteSettingsAck); err != nil {
+		conn.logger.Errorf("http2 write SETTINGS ack error: %s", err)
+	}
+}
+
+// handlePing ACKs a peer PING frame per RFC 7540 Section 6.7, for the same
+// reason handleSettings does.
+func (conn *streamConnection) handlePing(f *http2.PingFrame) {
+	if f.IsAck() {
+		return
+	}
+	if err := conn.writeFrame(func() error {
+		return conn.framer.WritePing(true, f.Data)
+	}); err != nil {
+		conn.logger.Errorf("http2 write PING ack error: %s", err)
+	}
+}
+
+// closeFlowControl marks the connection closed and wakes every AppendData
+// call blocked in acquireSendWindow, so a dead connection can't leave a
+// writer waiting on a WINDOW_UPDATE that will never come.
+func (conn *streamConnection) closeFlowControl() {
+	atomic.StoreInt32(&conn.closed, 1)
+	conn.flowCond.Broadcast()
+}
+
+// acquireSendWindow blocks until at least one byte of both the
+// connection-level and the stream's own send window is available, then
+// reserves up to want bytes (whichever is smaller) and returns how many
+// bytes the caller may send now; a caller with more than that must call
+// again for the remainder. It returns 0, errConnClose if the connection
+// closed while waiting.
+func (conn *streamConnection) acquireSendWindow(s *stream, want int) (int, error) {
+	conn.flowMu.Lock()
+	defer conn.flowMu.Unlock()
+
+	for conn.connSendWindow == 0 || atomic.LoadUint32(&s.sendWindow) == 0 {
+		if atomic.LoadInt32(&conn.closed) != 0 {
+			return 0, errConnClose
+		}
+		conn.flowCond.Wait()
+	}
+	if atomic.LoadInt32(&conn.closed) != 0 {
+		return 0, errConnClose
+	}
+
+	n := want
+	if avail := int(conn.connSendWindow); n > avail {
+		n = avail
+	}
+	if avail := int(atomic.LoadUint32(&s.sendWindow)); n > avail {
+		n = avail
+	}
+
+	conn.connSendWindow -= uint32(n)
+	atomic.AddUint32(&s.sendWindow, ^uint32(n-1)) // atomic subtract n
+	return n, nil
+}
+
+// writeDataFlowControlled sends b as one or more DATA frames, splitting it
+// to fit whatever the connection-level and per-stream send windows allow
+// at the time and blocking in between on WINDOW_UPDATE frames from the
+// peer, rather than writing the whole buffer regardless of window size.
+func (conn *streamConnection) writeDataFlowControlled(s *stream, b []byte, endStream bool) error {
+	if len(b) == 0 {
+		return conn.writeFrame(func() error {
+			return conn.framer.WriteData(s.streamID, endStream, b)
+		})
+	}
+
+	for len(b) > 0 {
+		n, err := conn.acquireSendWindow(s, len(b))
+		if err != nil {
+			return err
+		}
+		last := n == len(b)
+
+		if err := conn.writeFrame(func() error {
+			return conn.framer.WriteData(s.streamID, endStream && last, b[:n])
+		}); err != nil {
+			return err
+		}
+
+		b = b[n:]
+	}
+
+	return nil
+}
+
+// header is a minimal types.HeaderMap backed by the name/value pairs HPACK
+// decodes off the wire; the fasthttp-backed header types in
+// pkg/protocol/http don't apply here since there's no fasthttp.Request
+// underneath an HTTP/2 stream.
+type header map[string]string
+
+func (h header) Get(key string) (string, bool) {
+	v, ok := h[key]
+	return v, ok
+}
+
+func (h header) Set(key, value string) {
+	h[key] = value
+}
+
+func (h header) Del(key string) {
+	delete(h, key)
+}
+
+func (h header) Range(f func(key, value string) bool) {
+	for k, v := range h {
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
+func (h header) Clone() types.HeaderMap {
+	clone := make(header, len(h))
+	for k, v := range h {
+		clone[k] = v
+	}
+	return clone
+}
+
+func (h header) ByteSize() uint64 {
+	var size uint64
+	for k, v := range h {
+		size += uint64(len(k) + len(v))
+	}
+	return size
+}
+
+// writeHeaders HPACK-encodes h and sends it as a HEADERS frame. Encoding
+// and the frame write happen as one critical section under writeMutex:
+// henc/hbuf are shared by every stream on the connection, so encoding one
+// stream's headers outside the lock (with only the write itself
+// serialized) would let two concurrent AppendHeaders calls interleave
+// writes into the same HPACK dynamic table and garble the wire format for
+// the whole connection, not just the racing streams.
+func (conn *streamConnection) writeHeaders(streamID uint32, h types.HeaderMap, endStream bool) error {
+	conn.writeMutex.Lock()
+	defer conn.writeMutex.Unlock()
+
+	conn.hbuf.Reset()
+	h.Range(func(key, value string) bool {
+		conn.henc.WriteField(hpack.HeaderField{Name: key, Value: value})
+		return true
+	})
+
+	return conn.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: conn.hbuf.Bytes(),
+		EndHeaders:    true,
+		EndStream:     endStream,
+	})
+}
+
+// types.Stream
+// types.StreamSender
+//
+// Unlike the HTTP/1.1 codec's single "stream" pointer per connection, each
+// HTTP/2 stream carries its own RFC 7540 stream ID and flow-control
+// window, and many of these can be active concurrently.
+type stream struct {
+	str.BaseStream
+
+	id               uint64
+	streamID         uint32
+	readDisableCount int32
+	ctx              context.Context
+
+	// sendWindow is this stream's view of the peer's flow-control window;
+	// it is drained by outbound DATA frames and replenished by incoming
+	// WINDOW_UPDATE frames.
+	sendWindow uint32
+
+	// pendingMu guards pending: HEADERS/DATA frames that arrive while
+	// readDisableCount is positive are queued here instead of being
+	// delivered (and instead of their WINDOW_UPDATE credit being granted)
+	// so ReadDisable(false) can deliver them in order once the stream is
+	// ready for them, rather than dropping them on the floor.
+	pendingMu sync.Mutex
+	pending   []func()
+
+	// closeMu guards localClosed/remoteClosed: a stream is only removed
+	// from its connection's streams map (and so stops accepting
+	// WINDOW_UPDATE frames) once it's closed in both directions, per RFC
+	// 7540 Section 5.1 -- not as soon as the read side sees END_STREAM,
+	// which would cut off a response that's still being written.
+	closeMu      sync.Mutex
+	localClosed  bool
+	remoteClosed bool
+
+	receiver types.StreamReceiveListener
+}
+
+// markRemoteClosed records that the peer sent END_STREAM and reports
+// whether the stream is now closed in both directions.
+func (s *stream) markRemoteClosed() bool {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	s.remoteClosed = true
+	return s.localClosed
+}
+
+// markLocalClosed records that we sent END_STREAM and reports whether the
+// stream is now closed in both directions.
+func (s *stream) markLocalClosed() bool {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	s.localClosed = true
+	return s.remoteClosed
+}
+
+// deliverOrQueue runs fn immediately if the stream isn't currently paused
+// via ReadDisable, or queues it to run in arrival order once ReadDisable
+// flushes the backlog.
+func (s *stream) deliverOrQueue(fn func()) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if atomic.LoadInt32(&s.readDisableCount) > 0 {
+		s.pending = append(s.pending, fn)
+		return
+	}
+	fn()
+}
+
+// flushPending delivers any frames buffered by deliverOrQueue while the
+// stream was paused, in the order they arrived.
+func (s *stream) flushPending() {
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.pendingMu.Unlock()
+
+	for _, fn := range pending {
+		fn()
+	}
+}
+
+// types.Stream
+func (s *stream) ID() uint64 {
+	return s.id
+}
@@ -0,0 +1,37 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+// Mode describes how a stream's data frames should be interpreted by its
+// codec. Most streams are ModeRequest: each AppendData/OnReceiveData call
+// carries a chunk of an HTTP request or response body that the codec is
+// free to parse or re-frame. A stream that has been upgraded (e.g. by a
+// WebSocket handshake) switches to ModeTunnel, at which point data frames are
+// opaque bytes that must be relayed byte-for-byte between the two sides
+// of the proxy with no further protocol parsing.
+type Mode int
+
+const (
+	// ModeRequest is the default mode: a codec parses and frames
+	// request/response data normally.
+	ModeRequest Mode = iota
+	// ModeTunnel indicates the stream has been upgraded to a raw
+	// byte-stream tunnel (e.g. after a WebSocket 101 response), and data
+	// frames must be relayed verbatim.
+	ModeTunnel
+)